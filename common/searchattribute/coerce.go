@@ -0,0 +1,159 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package searchattribute
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+)
+
+// ErrIncompatibleTypes is returned by Coerce when converting between the payload's current type and the
+// requested target type would be lossy or otherwise unsafe, e.g. DOUBLE->INT or DATETIME->BOOL.
+var ErrIncompatibleTypes = errors.New("incompatible search attribute types")
+
+type typePair struct {
+	from enumspb.IndexedValueType
+	to   enumspb.IndexedValueType
+}
+
+type coercionFunc func(p *commonpb.Payload) (*commonpb.Payload, error)
+
+// coercions is table-driven so new safe type pairs (e.g. list element promotions) can be added without touching
+// call sites.
+var coercions = map[typePair]coercionFunc{
+	{enumspb.INDEXED_VALUE_TYPE_INT, enumspb.INDEXED_VALUE_TYPE_DOUBLE}:      coerceIntToDouble,
+	{enumspb.INDEXED_VALUE_TYPE_KEYWORD, enumspb.INDEXED_VALUE_TYPE_STRING}:  coerceRetype(enumspb.INDEXED_VALUE_TYPE_STRING),
+	{enumspb.INDEXED_VALUE_TYPE_STRING, enumspb.INDEXED_VALUE_TYPE_KEYWORD}:  coerceRetype(enumspb.INDEXED_VALUE_TYPE_KEYWORD),
+	{enumspb.INDEXED_VALUE_TYPE_STRING, enumspb.INDEXED_VALUE_TYPE_DATETIME}: coerceRFC3339ToDatetime,
+	{enumspb.INDEXED_VALUE_TYPE_INT, enumspb.INDEXED_VALUE_TYPE_DATETIME}:    coerceEpochMillisToDatetime,
+	{enumspb.INDEXED_VALUE_TYPE_BOOL, enumspb.INDEXED_VALUE_TYPE_STRING}:     coerceBoolToString,
+}
+
+// Coerce converts payload's encoded value from its current MetadataType to target, returning a new payload
+// stamped with target. It handles safe promotions (INT->DOUBLE, KEYWORD<->STRING, RFC3339 STRING->DATETIME,
+// epoch-millis INT->DATETIME, BOOL->STRING) and returns ErrIncompatibleTypes for any pair it doesn't recognize,
+// including lossy ones such as DOUBLE->INT.
+func Coerce(payload *commonpb.Payload, target enumspb.IndexedValueType) (*commonpb.Payload, error) {
+	current, hasType := metadataValueType(payload)
+	if !hasType {
+		return nil, fmt.Errorf("%w: payload has no %s metadata", ErrInvalidType, MetadataType)
+	}
+	if current == target {
+		return payload, nil
+	}
+
+	fn, ok := coercions[typePair{from: current, to: target}]
+	if !ok {
+		return nil, fmt.Errorf("%w: %v to %v", ErrIncompatibleTypes, current, target)
+	}
+	return fn(payload)
+}
+
+func metadataValueType(p *commonpb.Payload) (enumspb.IndexedValueType, bool) {
+	tString, ok := p.GetMetadata()[MetadataType]
+	if !ok {
+		return enumspb.INDEXED_VALUE_TYPE_UNSPECIFIED, false
+	}
+	ivt, isValid := enumspb.IndexedValueType_value[string(tString)]
+	if !isValid {
+		return enumspb.INDEXED_VALUE_TYPE_UNSPECIFIED, false
+	}
+	return enumspb.IndexedValueType(ivt), true
+}
+
+func coerceRetype(target enumspb.IndexedValueType) coercionFunc {
+	return func(p *commonpb.Payload) (*commonpb.Payload, error) {
+		return clonePayload(p, target, p.GetData()), nil
+	}
+}
+
+func coerceIntToDouble(p *commonpb.Payload) (*commonpb.Payload, error) {
+	var v int64
+	if err := json.Unmarshal(p.GetData(), &v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIncompatibleTypes, err)
+	}
+	data, err := json.Marshal(float64(v))
+	if err != nil {
+		return nil, err
+	}
+	return clonePayload(p, enumspb.INDEXED_VALUE_TYPE_DOUBLE, data), nil
+}
+
+func coerceRFC3339ToDatetime(p *commonpb.Payload) (*commonpb.Payload, error) {
+	var v string
+	if err := json.Unmarshal(p.GetData(), &v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIncompatibleTypes, err)
+	}
+	ts, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIncompatibleTypes, err)
+	}
+	data, err := json.Marshal(ts)
+	if err != nil {
+		return nil, err
+	}
+	return clonePayload(p, enumspb.INDEXED_VALUE_TYPE_DATETIME, data), nil
+}
+
+func coerceEpochMillisToDatetime(p *commonpb.Payload) (*commonpb.Payload, error) {
+	var v int64
+	if err := json.Unmarshal(p.GetData(), &v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIncompatibleTypes, err)
+	}
+	ts := time.UnixMilli(v).UTC()
+	data, err := json.Marshal(ts)
+	if err != nil {
+		return nil, err
+	}
+	return clonePayload(p, enumspb.INDEXED_VALUE_TYPE_DATETIME, data), nil
+}
+
+func coerceBoolToString(p *commonpb.Payload) (*commonpb.Payload, error) {
+	var v bool
+	if err := json.Unmarshal(p.GetData(), &v); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIncompatibleTypes, err)
+	}
+	data, err := json.Marshal(strconv.FormatBool(v))
+	if err != nil {
+		return nil, err
+	}
+	return clonePayload(p, enumspb.INDEXED_VALUE_TYPE_STRING, data), nil
+}
+
+func clonePayload(p *commonpb.Payload, t enumspb.IndexedValueType, data []byte) *commonpb.Payload {
+	metadata := make(map[string][]byte, len(p.GetMetadata()))
+	for k, v := range p.GetMetadata() {
+		metadata[k] = v
+	}
+	out := &commonpb.Payload{Metadata: metadata, Data: data}
+	setMetadataType(out, t)
+	return out
+}
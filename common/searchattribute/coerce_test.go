@@ -0,0 +1,130 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package searchattribute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+)
+
+func payloadOfType(data string, t enumspb.IndexedValueType) *commonpb.Payload {
+	p := &commonpb.Payload{Data: []byte(data), Metadata: map[string][]byte{}}
+	setMetadataType(p, t)
+	return p
+}
+
+func TestCoerce_IntToDouble(t *testing.T) {
+	p := payloadOfType(`7`, enumspb.INDEXED_VALUE_TYPE_INT)
+
+	out, err := Coerce(p, enumspb.INDEXED_VALUE_TYPE_DOUBLE)
+	require.NoError(t, err)
+	require.Equal(t, "7", string(out.Data))
+	require.Equal(t, "Double", string(out.Metadata[MetadataType]))
+}
+
+func TestCoerce_KeywordToString_AndBack(t *testing.T) {
+	p := payloadOfType(`"active"`, enumspb.INDEXED_VALUE_TYPE_KEYWORD)
+
+	out, err := Coerce(p, enumspb.INDEXED_VALUE_TYPE_STRING)
+	require.NoError(t, err)
+	require.Equal(t, "String", string(out.Metadata[MetadataType]))
+
+	back, err := Coerce(out, enumspb.INDEXED_VALUE_TYPE_KEYWORD)
+	require.NoError(t, err)
+	require.Equal(t, "Keyword", string(back.Metadata[MetadataType]))
+}
+
+func TestCoerce_StringToDatetime_RFC3339(t *testing.T) {
+	p := payloadOfType(`"2023-01-02T03:04:05Z"`, enumspb.INDEXED_VALUE_TYPE_STRING)
+
+	out, err := Coerce(p, enumspb.INDEXED_VALUE_TYPE_DATETIME)
+	require.NoError(t, err)
+	require.Equal(t, "Datetime", string(out.Metadata[MetadataType]))
+}
+
+func TestCoerce_IntToDatetime_EpochMillis(t *testing.T) {
+	p := payloadOfType(`1672628645000`, enumspb.INDEXED_VALUE_TYPE_INT)
+
+	out, err := Coerce(p, enumspb.INDEXED_VALUE_TYPE_DATETIME)
+	require.NoError(t, err)
+	require.Equal(t, "Datetime", string(out.Metadata[MetadataType]))
+}
+
+func TestCoerce_BoolToString(t *testing.T) {
+	p := payloadOfType(`true`, enumspb.INDEXED_VALUE_TYPE_BOOL)
+
+	out, err := Coerce(p, enumspb.INDEXED_VALUE_TYPE_STRING)
+	require.NoError(t, err)
+	require.Equal(t, `"true"`, string(out.Data))
+}
+
+func TestCoerce_SameType_IsNoop(t *testing.T) {
+	p := payloadOfType(`"active"`, enumspb.INDEXED_VALUE_TYPE_KEYWORD)
+
+	out, err := Coerce(p, enumspb.INDEXED_VALUE_TYPE_KEYWORD)
+	require.NoError(t, err)
+	require.Same(t, p, out)
+}
+
+func TestCoerce_LossyConversionRejected(t *testing.T) {
+	p := payloadOfType(`3.14`, enumspb.INDEXED_VALUE_TYPE_DOUBLE)
+
+	_, err := Coerce(p, enumspb.INDEXED_VALUE_TYPE_INT)
+	require.ErrorIs(t, err, ErrIncompatibleTypes)
+}
+
+func TestApplyTypeMapWithCoercion_CoercesOnMismatch(t *testing.T) {
+	sa := &commonpb.SearchAttributes{
+		IndexedFields: map[string]*commonpb.Payload{
+			"CustomIntField": payloadOfType(`5`, enumspb.INDEXED_VALUE_TYPE_INT),
+		},
+	}
+	typeMap := map[string]enumspb.IndexedValueType{
+		"CustomIntField": enumspb.INDEXED_VALUE_TYPE_DOUBLE,
+	}
+
+	err := ApplyTypeMapWithCoercion(sa, typeMap, true)
+	require.NoError(t, err)
+	require.Equal(t, "Double", string(sa.IndexedFields["CustomIntField"].Metadata[MetadataType]))
+}
+
+func TestApplyTypeMapWithCoercion_SkipsMismatchWithoutCoercion(t *testing.T) {
+	sa := &commonpb.SearchAttributes{
+		IndexedFields: map[string]*commonpb.Payload{
+			"CustomIntField": payloadOfType(`5`, enumspb.INDEXED_VALUE_TYPE_INT),
+		},
+	}
+	typeMap := map[string]enumspb.IndexedValueType{
+		"CustomIntField": enumspb.INDEXED_VALUE_TYPE_DOUBLE,
+	}
+
+	err := ApplyTypeMapWithCoercion(sa, typeMap, false)
+	require.NoError(t, err)
+	require.Equal(t, "Int", string(sa.IndexedFields["CustomIntField"].Metadata[MetadataType]))
+}
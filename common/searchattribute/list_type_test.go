@@ -0,0 +1,73 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package searchattribute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+)
+
+func TestGetESType_ListTypes(t *testing.T) {
+	require.Equal(t, "keyword", GetESType(enumspb.INDEXED_VALUE_TYPE_KEYWORD_LIST))
+}
+
+func TestConvertDynamicConfigType_ListTypes(t *testing.T) {
+	ivt, err := convertDynamicConfigType("KeywordList")
+	require.NoError(t, err)
+	require.Equal(t, enumspb.INDEXED_VALUE_TYPE_KEYWORD_LIST, ivt)
+}
+
+func TestBuildTypeMap_ListTypes(t *testing.T) {
+	typeMapFn := func() map[string]interface{} {
+		return map[string]interface{}{"CustomKeywordListField": "KeywordList"}
+	}
+
+	typeMap, err := BuildTypeMap(typeMapFn)
+	require.NoError(t, err)
+	require.Equal(t, enumspb.INDEXED_VALUE_TYPE_KEYWORD_LIST, typeMap["CustomKeywordListField"])
+}
+
+func TestApplyTypeMap_ListType_RoundTrip(t *testing.T) {
+	sa := &commonpb.SearchAttributes{
+		IndexedFields: map[string]*commonpb.Payload{
+			"CustomKeywordListField": {Data: []byte(`["a","b"]`), Metadata: map[string][]byte{}},
+		},
+	}
+	typeMap := map[string]enumspb.IndexedValueType{
+		"CustomKeywordListField": enumspb.INDEXED_VALUE_TYPE_KEYWORD_LIST,
+	}
+
+	ApplyTypeMap(sa, typeMap)
+
+	p := sa.IndexedFields["CustomKeywordListField"]
+	require.Equal(t, "KeywordList", string(p.Metadata[MetadataType]))
+	require.Equal(t, "Keyword", string(p.Metadata[MetadataElementType]))
+	require.True(t, IsListType(enumspb.INDEXED_VALUE_TYPE_KEYWORD_LIST))
+	require.Equal(t, enumspb.INDEXED_VALUE_TYPE_KEYWORD, ElementType(enumspb.INDEXED_VALUE_TYPE_KEYWORD_LIST))
+}
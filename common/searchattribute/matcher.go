@@ -0,0 +1,201 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package searchattribute
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+)
+
+// Matcher evaluates a compiled expression against a SearchAttributes payload directly, without an Elasticsearch
+// round-trip. It is used for standard-visibility deployments, per-namespace subscription filters, and tests.
+type Matcher struct {
+	program cel.Program
+	typeMap map[string]enumspb.IndexedValueType
+}
+
+// Compile builds a CEL environment in which every attribute registered in typeMap becomes a typed variable, then
+// compiles expr against that environment. It fails at registration time, not per event, if expr references an
+// attribute not present in typeMap (ErrInvalidName) or compares values of incompatible types (ErrInvalidType).
+func Compile(expr string, typeMap map[string]enumspb.IndexedValueType) (Matcher, error) {
+	if len(typeMap) == 0 {
+		return Matcher{}, ErrTypeMapIsEmpty
+	}
+
+	opts := make([]cel.EnvOption, 0, len(typeMap))
+	for saName, saType := range typeMap {
+		celType, err := celType(saType)
+		if err != nil {
+			return Matcher{}, fmt.Errorf("%s: %w", saName, err)
+		}
+		opts = append(opts, cel.Variable(saName, celType))
+	}
+
+	env, err := cel.NewEnv(opts...)
+	if err != nil {
+		return Matcher{}, fmt.Errorf("failed to build CEL environment: %w", err)
+	}
+
+	ast, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		return Matcher{}, compileError(issues)
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return Matcher{}, fmt.Errorf("failed to build CEL program: %w", err)
+	}
+
+	return Matcher{program: program, typeMap: typeMap}, nil
+}
+
+// Matches decodes sa against the Matcher's type map and evaluates the compiled expression, returning its boolean
+// result. Attributes absent from sa are left unbound; referencing one in expr evaluates to CEL's "no such attribute".
+func (m Matcher) Matches(sa *commonpb.SearchAttributes) (bool, error) {
+	vars := make(map[string]interface{}, len(m.typeMap))
+	for saName, saType := range m.typeMap {
+		saPayload, ok := sa.GetIndexedFields()[saName]
+		if !ok {
+			continue
+		}
+		value, err := decodeValue(saPayload, saType)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", saName, err)
+		}
+		vars[saName] = value
+	}
+
+	out, _, err := m.program.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: expression must evaluate to a bool", ErrInvalidType)
+	}
+	return result, nil
+}
+
+// compileError classifies a CEL compilation failure as ErrInvalidName (expr references an attribute not
+// declared in the type map) or ErrInvalidType (expr compares or combines values of incompatible types), since
+// env.Compile runs parsing, name resolution, and type-checking together and reports both kinds of failure
+// through the same Issues value.
+func compileError(issues *cel.Issues) error {
+	for _, issue := range issues.Errors() {
+		if strings.Contains(issue.Message, "undeclared reference") {
+			return fmt.Errorf("%w: %v", ErrInvalidName, issues.Err())
+		}
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidType, issues.Err())
+}
+
+// celType returns the CEL type for t, including list types (e.g. KeywordList -> list(string)). typeMap entries
+// for a namespace commonly include list-valued attributes even when a given expr never references them, since
+// Compile binds every entry in typeMap, not just the ones expr uses.
+func celType(t enumspb.IndexedValueType) (*cel.Type, error) {
+	if IsListType(t) {
+		elemType, err := scalarCelType(ElementType(t))
+		if err != nil {
+			return nil, err
+		}
+		return cel.ListType(elemType), nil
+	}
+	return scalarCelType(t)
+}
+
+func scalarCelType(t enumspb.IndexedValueType) (*cel.Type, error) {
+	switch t {
+	case enumspb.INDEXED_VALUE_TYPE_STRING, enumspb.INDEXED_VALUE_TYPE_KEYWORD:
+		return cel.StringType, nil
+	case enumspb.INDEXED_VALUE_TYPE_INT:
+		return cel.IntType, nil
+	case enumspb.INDEXED_VALUE_TYPE_DOUBLE:
+		return cel.DoubleType, nil
+	case enumspb.INDEXED_VALUE_TYPE_BOOL:
+		return cel.BoolType, nil
+	case enumspb.INDEXED_VALUE_TYPE_DATETIME:
+		return cel.TimestampType, nil
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrInvalidType, t)
+	}
+}
+
+func decodeValue(p *commonpb.Payload, t enumspb.IndexedValueType) (interface{}, error) {
+	if IsListType(t) {
+		return decodeListValue(p, ElementType(t))
+	}
+	return decodeScalarValue(p.GetData(), t)
+}
+
+func decodeListValue(p *commonpb.Payload, elemType enumspb.IndexedValueType) (interface{}, error) {
+	var rawElems []json.RawMessage
+	if err := json.Unmarshal(p.GetData(), &rawElems); err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(rawElems))
+	for i, raw := range rawElems {
+		value, err := decodeScalarValue(raw, elemType)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func decodeScalarValue(data []byte, t enumspb.IndexedValueType) (interface{}, error) {
+	switch t {
+	case enumspb.INDEXED_VALUE_TYPE_STRING, enumspb.INDEXED_VALUE_TYPE_KEYWORD:
+		var v string
+		err := json.Unmarshal(data, &v)
+		return v, err
+	case enumspb.INDEXED_VALUE_TYPE_INT:
+		var v int64
+		err := json.Unmarshal(data, &v)
+		return v, err
+	case enumspb.INDEXED_VALUE_TYPE_DOUBLE:
+		var v float64
+		err := json.Unmarshal(data, &v)
+		return v, err
+	case enumspb.INDEXED_VALUE_TYPE_BOOL:
+		var v bool
+		err := json.Unmarshal(data, &v)
+		return v, err
+	case enumspb.INDEXED_VALUE_TYPE_DATETIME:
+		var v time.Time
+		err := json.Unmarshal(data, &v)
+		return v, err
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrInvalidType, t)
+	}
+}
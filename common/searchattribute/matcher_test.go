@@ -0,0 +1,134 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Copyright (c) 2020 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package searchattribute
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+)
+
+func stringPayload(t *testing.T, v string, it enumspb.IndexedValueType) *commonpb.Payload {
+	p := &commonpb.Payload{Data: []byte(`"` + v + `"`), Metadata: map[string][]byte{}}
+	setMetadataType(p, it)
+	return p
+}
+
+func intPayload(t *testing.T, v int64, it enumspb.IndexedValueType) *commonpb.Payload {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	p := &commonpb.Payload{Data: data, Metadata: map[string][]byte{}}
+	setMetadataType(p, it)
+	return p
+}
+
+func TestMatcher_Matches(t *testing.T) {
+	typeMap := map[string]enumspb.IndexedValueType{
+		"CustomKeywordField": enumspb.INDEXED_VALUE_TYPE_KEYWORD,
+		"CustomIntField":     enumspb.INDEXED_VALUE_TYPE_INT,
+	}
+
+	matcher, err := Compile(`CustomKeywordField == "active" && CustomIntField > 10`, typeMap)
+	require.NoError(t, err)
+
+	sa := &commonpb.SearchAttributes{
+		IndexedFields: map[string]*commonpb.Payload{
+			"CustomKeywordField": stringPayload(t, "active", enumspb.INDEXED_VALUE_TYPE_KEYWORD),
+			"CustomIntField":     intPayload(t, 42, enumspb.INDEXED_VALUE_TYPE_INT),
+		},
+	}
+
+	matched, err := matcher.Matches(sa)
+	require.NoError(t, err)
+	require.True(t, matched)
+}
+
+func TestCompile_IgnoresUnreferencedListTypedAttribute(t *testing.T) {
+	// A namespace's type map commonly includes list-valued attributes that a given query never mentions;
+	// Compile binds a CEL variable for every typeMap entry, so list types must not break unrelated queries.
+	typeMap := map[string]enumspb.IndexedValueType{
+		"CustomKeywordField": enumspb.INDEXED_VALUE_TYPE_KEYWORD,
+		"CustomLabelsField":  enumspb.INDEXED_VALUE_TYPE_KEYWORD_LIST,
+	}
+
+	matcher, err := Compile(`CustomKeywordField == "active"`, typeMap)
+	require.NoError(t, err)
+
+	sa := &commonpb.SearchAttributes{
+		IndexedFields: map[string]*commonpb.Payload{
+			"CustomKeywordField": stringPayload(t, "active", enumspb.INDEXED_VALUE_TYPE_KEYWORD),
+		},
+	}
+
+	matched, err := matcher.Matches(sa)
+	require.NoError(t, err)
+	require.True(t, matched)
+}
+
+func TestMatcher_Matches_ListType(t *testing.T) {
+	typeMap := map[string]enumspb.IndexedValueType{
+		"CustomLabelsField": enumspb.INDEXED_VALUE_TYPE_KEYWORD_LIST,
+	}
+
+	matcher, err := Compile(`"urgent" in CustomLabelsField`, typeMap)
+	require.NoError(t, err)
+
+	p := &commonpb.Payload{Data: []byte(`["urgent","billing"]`), Metadata: map[string][]byte{}}
+	setMetadataType(p, enumspb.INDEXED_VALUE_TYPE_KEYWORD_LIST)
+	sa := &commonpb.SearchAttributes{
+		IndexedFields: map[string]*commonpb.Payload{"CustomLabelsField": p},
+	}
+
+	matched, err := matcher.Matches(sa)
+	require.NoError(t, err)
+	require.True(t, matched)
+}
+
+func TestCompile_UnknownAttribute(t *testing.T) {
+	typeMap := map[string]enumspb.IndexedValueType{
+		"CustomKeywordField": enumspb.INDEXED_VALUE_TYPE_KEYWORD,
+	}
+
+	_, err := Compile(`NotRegistered == "x"`, typeMap)
+	require.ErrorIs(t, err, ErrInvalidName)
+}
+
+func TestCompile_TypeMismatch(t *testing.T) {
+	typeMap := map[string]enumspb.IndexedValueType{
+		"CustomIntField": enumspb.INDEXED_VALUE_TYPE_INT,
+	}
+
+	_, err := Compile(`CustomIntField == "not a number"`, typeMap)
+	require.ErrorIs(t, err, ErrInvalidType)
+}
+
+func TestCompile_EmptyTypeMap(t *testing.T) {
+	_, err := Compile(`true`, nil)
+	require.ErrorIs(t, err, ErrTypeMapIsEmpty)
+}
@@ -36,6 +36,9 @@ import (
 
 const (
 	MetadataType = "type"
+	// MetadataElementType carries the scalar IndexedValueType of a list-valued search attribute, e.g. a
+	// KeywordList payload stamps MetadataType with "KeywordList" and MetadataElementType with "Keyword".
+	MetadataElementType = "elementType"
 )
 
 var (
@@ -81,21 +84,53 @@ func GetType(name string, typeMap map[string]enumspb.IndexedValueType) (enumspb.
 // ApplyTypeMap set type for all valid search attributes which don't have it.
 // It doesn't do any validation and just skip invalid or already set search attributes.
 func ApplyTypeMap(searchAttributes *commonpb.SearchAttributes, typeMap map[string]enumspb.IndexedValueType) {
+	// coerceMismatched=false means applyTypeMap never calls Coerce, so it can't return an error.
+	_ = applyTypeMap(searchAttributes, typeMap, false)
+}
+
+// ApplyTypeMapWithCoercion behaves like ApplyTypeMap, but additionally handles search attributes whose existing
+// MetadataType disagrees with typeMap: if coerceMismatched is true, the payload is converted via Coerce instead
+// of being left as-is; this lets an operator change a search attribute's declared type in dynamic config without
+// breaking workflows whose in-flight payloads still carry the old encoding.
+//
+// Coercion is all-or-nothing: every mismatched payload is coerced before any of them is written back, so a
+// failure partway through never leaves searchAttributes with some fields migrated and others not.
+func ApplyTypeMapWithCoercion(searchAttributes *commonpb.SearchAttributes, typeMap map[string]enumspb.IndexedValueType, coerceMismatched bool) error {
+	return applyTypeMap(searchAttributes, typeMap, coerceMismatched)
+}
+
+func applyTypeMap(searchAttributes *commonpb.SearchAttributes, typeMap map[string]enumspb.IndexedValueType, coerceMismatched bool) error {
 	if len(typeMap) == 0 {
-		return
+		return nil
 	}
 
+	coercedPayloads := make(map[string]*commonpb.Payload)
 	for saName, saPayload := range searchAttributes.GetIndexedFields() {
-		_, metadataHasValueType := saPayload.Metadata[MetadataType]
-		if metadataHasValueType {
-			continue
-		}
 		valueType, isDefined := typeMap[saName]
 		if !isDefined {
 			continue
 		}
-		setMetadataType(saPayload, valueType)
+
+		if _, metadataHasValueType := saPayload.GetMetadata()[MetadataType]; !metadataHasValueType {
+			setMetadataType(saPayload, valueType)
+			continue
+		}
+		existingType, isKnownType := metadataValueType(saPayload)
+		if !isKnownType || existingType == valueType || !coerceMismatched {
+			continue
+		}
+
+		coerced, err := Coerce(saPayload, valueType)
+		if err != nil {
+			return fmt.Errorf("%s: %w", saName, err)
+		}
+		coercedPayloads[saName] = coerced
 	}
+
+	for saName, coerced := range coercedPayloads {
+		searchAttributes.GetIndexedFields()[saName] = coerced
+	}
+	return nil
 }
 
 func GetESType(t enumspb.IndexedValueType) string {
@@ -112,14 +147,44 @@ func GetESType(t enumspb.IndexedValueType) string {
 		return "boolean"
 	case enumspb.INDEXED_VALUE_TYPE_DATETIME:
 		return "date"
+	case enumspb.INDEXED_VALUE_TYPE_KEYWORD_LIST:
+		// ES treats every field as implicitly multi-valued, so a keyword list uses the same mapping as a
+		// scalar keyword; it's the payload contract (JSON array vs scalar) that changes, not the ES type.
+		return "keyword"
 	default:
 		return ""
 	}
 }
 
+// IsListType returns true if t represents a list-valued search attribute.
+//
+// Only INDEXED_VALUE_TYPE_KEYWORD_LIST is supported today, since it's the only list IndexedValueType this
+// server's go.temporal.io/api dependency currently defines. Other element types (string, int, ...) can be added
+// here and to ElementType once the corresponding enum values land upstream.
+func IsListType(t enumspb.IndexedValueType) bool {
+	switch t {
+	case enumspb.INDEXED_VALUE_TYPE_KEYWORD_LIST:
+		return true
+	default:
+		return false
+	}
+}
+
+// ElementType returns the scalar IndexedValueType carried by each element of a list type t.
+// It returns t unchanged if t is not a list type.
+func ElementType(t enumspb.IndexedValueType) enumspb.IndexedValueType {
+	switch t {
+	case enumspb.INDEXED_VALUE_TYPE_KEYWORD_LIST:
+		return enumspb.INDEXED_VALUE_TYPE_KEYWORD
+	default:
+		return t
+	}
+}
+
 // convertDynamicConfigType takes dynamicConfigType as interface{} and convert to IndexedValueType.
 // This func is needed because different implementation of dynamic config client may have different type of dynamicConfigType
-// and to support backward compatibility.
+// and to support backward compatibility. The string form also covers list types (e.g. "KeywordList"), since it
+// resolves against the full enumspb.IndexedValueType_value name table rather than a hardcoded subset.
 func convertDynamicConfigType(dynamicConfigType interface{}) (enumspb.IndexedValueType, error) {
 	switch t := dynamicConfigType.(type) {
 	case float64:
@@ -155,4 +220,10 @@ func setMetadataType(p *commonpb.Payload, t enumspb.IndexedValueType) {
 		panic(fmt.Sprintf("unknown index value type %v", t))
 	}
 	p.Metadata[MetadataType] = []byte(tString)
+
+	if IsListType(t) {
+		// Downstream serializers use MetadataElementType to know they must encode a JSON array of this
+		// scalar type rather than a scalar value.
+		p.Metadata[MetadataElementType] = []byte(enumspb.IndexedValueType_name[int32(ElementType(t))])
+	}
 }